@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// EmailActivationThrottle tracks activation-code send history for a single
+// (UserId, Email) pair so EmailService.SendEmailActivationCode can enforce
+// rate limits that survive process restarts. WindowStart and CountInWindow
+// implement the hourly cap, counted in a fixed window that starts at
+// WindowStart and resets once an hour has passed; LastSentAt implements
+// the resend cooldown.
+type EmailActivationThrottle struct {
+	Id            int
+	UserId        int    `gorm:"unique_index:idx_email_activation_throttle_user_email"`
+	Email         string `gorm:"unique_index:idx_email_activation_throttle_user_email"`
+	LastSentAt    time.Time
+	WindowStart   time.Time
+	CountInWindow int
+	Created       time.Time
+	Updated       time.Time
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// EmailAudit records a single administrative action taken against an
+// Email record so support actions (forced verification, forced deletion)
+// remain traceable after the fact.
+type EmailAudit struct {
+	Id            int
+	ActorUserId   int
+	TargetEmailId int
+	Action        string
+	BeforeState   string
+	AfterState    string
+	Created       time.Time
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gocms-io/gocms/models"
+	"github.com/jinzhu/gorm"
+)
+
+type IEmailActivationThrottleRepository interface {
+	GetByUserAndEmail(userId int, email string) (*models.EmailActivationThrottle, error)
+	CheckAndRecordSend(userId int, email string, cooldown time.Duration, hourlyCap int, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
+
+type EmailActivationThrottleRepository struct {
+	DB *gorm.DB
+}
+
+func DefaultEmailActivationThrottleRepository(db *gorm.DB) *EmailActivationThrottleRepository {
+	return &EmailActivationThrottleRepository{DB: db}
+}
+
+func (r *EmailActivationThrottleRepository) GetByUserAndEmail(userId int, email string) (*models.EmailActivationThrottle, error) {
+	throttle := &models.EmailActivationThrottle{}
+	if err := r.DB.Where("user_id = ? AND email = ?", userId, email).First(throttle).Error; err != nil {
+		return nil, err
+	}
+	return throttle, nil
+}
+
+// CheckAndRecordSend atomically applies the resend cooldown and hourly cap
+// against now and, if the send is allowed, records it in the same
+// transaction under a row lock (SELECT ... FOR UPDATE). This closes the
+// check-then-write race a separate GetByUserAndEmail + Upsert pair would
+// have: two concurrent callers for the same (userId, email) can't both
+// observe "allowed" before either of them records a send. sqlite has no
+// FOR UPDATE clause, so on sqlite the read is issued without it and takes
+// no row lock.
+//
+// SELECT ... FOR UPDATE can't lock a row that doesn't exist yet, so two
+// concurrent first-ever sends for the same pair can both reach the
+// not-found branch. idx_email_activation_throttle_user_email (a unique
+// index on UserId, Email) turns the loser's Create into a conflict error
+// instead of a second row; on that conflict we call CheckAndRecordSend
+// again, which finds the row the winner inserted. The retry is not
+// bounded: each further conflict calls it again.
+func (r *EmailActivationThrottleRepository) CheckAndRecordSend(userId int, email string, cooldown time.Duration, hourlyCap int, now time.Time) (allowed bool, retryAfter time.Duration, err error) {
+
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return false, 0, tx.Error
+	}
+
+	throttle := &models.EmailActivationThrottle{}
+	query := tx.Where("user_id = ? AND email = ?", userId, email)
+	if tx.Dialect().GetName() != "sqlite3" {
+		query = query.Set("gorm:query_option", "FOR UPDATE")
+	}
+	findErr := query.First(throttle).Error
+
+	if findErr != nil && findErr != gorm.ErrRecordNotFound {
+		tx.Rollback()
+		return false, 0, findErr
+	}
+
+	if findErr == gorm.ErrRecordNotFound {
+		createErr := tx.Create(&models.EmailActivationThrottle{
+			UserId:        userId,
+			Email:         email,
+			LastSentAt:    now,
+			WindowStart:   now,
+			CountInWindow: 1,
+		}).Error
+
+		if createErr != nil {
+			tx.Rollback()
+			if !isUniqueConstraintErr(createErr) {
+				return false, 0, createErr
+			}
+			// Lost the race to a concurrent first-send: retry so this call
+			// takes the existing-row branch against the row the winner created.
+			return r.CheckAndRecordSend(userId, email, cooldown, hourlyCap, now)
+		}
+
+		return true, 0, tx.Commit().Error
+	}
+
+	if since := now.Sub(throttle.LastSentAt); since < cooldown {
+		tx.Rollback()
+		return false, cooldown - since, nil
+	}
+
+	windowElapsed := now.Sub(throttle.WindowStart)
+	if windowElapsed < time.Hour && throttle.CountInWindow >= hourlyCap {
+		tx.Rollback()
+		return false, time.Hour - windowElapsed, nil
+	}
+
+	if windowElapsed >= time.Hour {
+		throttle.WindowStart = now
+		throttle.CountInWindow = 0
+	}
+	throttle.LastSentAt = now
+	throttle.CountInWindow++
+
+	if err := tx.Save(throttle).Error; err != nil {
+		tx.Rollback()
+		return false, 0, err
+	}
+
+	return true, 0, tx.Commit().Error
+}
+
+// isUniqueConstraintErr reports whether err is a unique constraint
+// violation. gorm doesn't expose a driver-agnostic sentinel for this, so we
+// match on the substrings the sqlite3 and MySQL drivers both use.
+func isUniqueConstraintErr(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "unique constraint") || strings.Contains(msg, "duplicate entry")
+}
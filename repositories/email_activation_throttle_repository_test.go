@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocms-io/gocms/models"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func newTestEmailActivationThrottleRepository(t *testing.T) *EmailActivationThrottleRepository {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %s", err.Error())
+	}
+
+	// See newTestEmailRepository in email_repository_replace_pending_primary_test.go:
+	// a single connection with a busy timeout makes concurrent transactions
+	// queue and serialize instead of one erroring out immediately, so a
+	// concurrency test checks the outcome of two overlapping calls rather
+	// than tripping over a locking error.
+	db.DB().SetMaxOpenConns(1)
+	if err := db.Exec("PRAGMA busy_timeout = 5000").Error; err != nil {
+		t.Fatalf("setting busy_timeout: %s", err.Error())
+	}
+
+	if err := db.AutoMigrate(&models.EmailActivationThrottle{}).Error; err != nil {
+		t.Fatalf("migrating test db: %s", err.Error())
+	}
+
+	return &EmailActivationThrottleRepository{DB: db}
+}
+
+func TestCheckAndRecordSendAllowsFirstSend(t *testing.T) {
+	repo := newTestEmailActivationThrottleRepository(t)
+
+	allowed, _, err := repo.CheckAndRecordSend(1, "user@example.com", time.Minute, 5, time.Now())
+	if err != nil {
+		t.Fatalf("CheckAndRecordSend: %s", err.Error())
+	}
+	if !allowed {
+		t.Error("expected the first send to be allowed")
+	}
+}
+
+func TestCheckAndRecordSendRejectsWithinCooldown(t *testing.T) {
+	repo := newTestEmailActivationThrottleRepository(t)
+
+	now := time.Now()
+	if _, _, err := repo.CheckAndRecordSend(1, "user@example.com", time.Minute, 5, now); err != nil {
+		t.Fatalf("first send: %s", err.Error())
+	}
+
+	allowed, retryAfter, err := repo.CheckAndRecordSend(1, "user@example.com", time.Minute, 5, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("second send: %s", err.Error())
+	}
+	if allowed {
+		t.Error("expected the second send within the cooldown to be rejected")
+	}
+	if retryAfter <= 0 {
+		t.Errorf("expected a positive retryAfter, got %s", retryAfter)
+	}
+}
+
+// TestCheckAndRecordSendConcurrentFirstSendsAreSerialized fires two
+// concurrent first-ever sends for the same (userId, email) pair. The test
+// db has a single connection, so the second transaction begins only after
+// the first has committed its row; it finds that row and is rejected by
+// the cooldown. Exactly one of the two calls records the send and the pair
+// never ends up with two rows.
+func TestCheckAndRecordSendConcurrentFirstSendsAreSerialized(t *testing.T) {
+	repo := newTestEmailActivationThrottleRepository(t)
+
+	now := time.Now()
+	results := make([]bool, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			allowed, _, err := repo.CheckAndRecordSend(1, "user@example.com", time.Minute, 5, now)
+			if err != nil {
+				t.Errorf("CheckAndRecordSend: %s", err.Error())
+				return
+			}
+			results[i] = allowed
+		}(i)
+	}
+	wg.Wait()
+
+	allowedCount := 0
+	for _, allowed := range results {
+		if allowed {
+			allowedCount++
+		}
+	}
+	if allowedCount != 1 {
+		t.Errorf("expected exactly one of the two concurrent first sends to be allowed, got %d", allowedCount)
+	}
+
+	var rows []models.EmailActivationThrottle
+	if err := repo.DB.Where("user_id = ? AND email = ?", 1, "user@example.com").Find(&rows).Error; err != nil {
+		t.Fatalf("querying throttle rows: %s", err.Error())
+	}
+	if len(rows) != 1 {
+		t.Errorf("expected exactly one throttle row for the pair, got %d", len(rows))
+	}
+}
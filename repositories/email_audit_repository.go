@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"github.com/gocms-io/gocms/models"
+	"github.com/jinzhu/gorm"
+)
+
+type IEmailAuditRepository interface {
+	Add(audit *models.EmailAudit) error
+}
+
+type EmailAuditRepository struct {
+	DB *gorm.DB
+}
+
+func DefaultEmailAuditRepository(db *gorm.DB) *EmailAuditRepository {
+	return &EmailAuditRepository{DB: db}
+}
+
+func (r *EmailAuditRepository) Add(audit *models.EmailAudit) error {
+	return r.DB.Create(audit).Error
+}
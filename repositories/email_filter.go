@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+)
+
+// EmailFilter narrows an administrative listing of Email records. The zero
+// value of each field is treated as "no constraint" for that field.
+type EmailFilter struct {
+	Verified      *bool
+	DomainGlob    string
+	UserId        int
+	CreatedBefore time.Time
+}
+
+// DomainGlobMatch matches domain against pattern, where pattern may start
+// with "*." to match any subdomain of the remainder. Shared by EmailFilter
+// and services.EmailDomainPolicy so the two don't maintain separate copies
+// of the same matching rule.
+func DomainGlobMatch(pattern string, domain string) bool {
+	pattern = strings.ToLower(pattern)
+	domain = strings.ToLower(domain)
+
+	if pattern == domain {
+		return true
+	}
+
+	if strings.HasPrefix(pattern, "*.") {
+		return strings.HasSuffix(domain, pattern[1:])
+	}
+
+	return false
+}
+
+// emailAddressDomain returns the part of address after the last "@".
+func emailAddressDomain(address string) string {
+	at := strings.LastIndex(address, "@")
+	if at < 0 {
+		return ""
+	}
+	return address[at+1:]
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import "github.com/gocms-io/gocms/models"
+
+// Find returns every Email matching filter, used by administrative
+// tooling. DomainGlob is applied in-process since it isn't expressible as
+// a plain SQL predicate.
+func (r *EmailRepository) Find(filter EmailFilter) ([]models.Email, error) {
+	query := r.DB.Model(&models.Email{})
+
+	if filter.Verified != nil {
+		query = query.Where("is_verified = ?", *filter.Verified)
+	}
+
+	if filter.UserId != 0 {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created < ?", filter.CreatedBefore)
+	}
+
+	var emails []models.Email
+	if err := query.Find(&emails).Error; err != nil {
+		return nil, err
+	}
+
+	if filter.DomainGlob == "" {
+		return emails, nil
+	}
+
+	matched := emails[:0]
+	for _, e := range emails {
+		if DomainGlobMatch(filter.DomainGlob, emailAddressDomain(e.Email)) {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
@@ -0,0 +1,11 @@
+package repositories
+
+import "github.com/gocms-io/gocms/models"
+
+func (r *EmailRepository) GetById(id int) (*models.Email, error) {
+	email := &models.Email{}
+	if err := r.DB.Where("id = ?", id).First(email).Error; err != nil {
+		return nil, err
+	}
+	return email, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"github.com/gocms-io/gocms/utility/errors"
+
+	"github.com/gocms-io/gocms/models"
+)
+
+// ReplacePendingPrimary atomically swaps a user's unverified primary email
+// for a new address within a single transaction, so a reader can never
+// observe the account with zero primaries or with two. Callers are
+// responsible for confirming the account has no verified email before
+// calling this; it does not re-check that invariant itself.
+func (r *EmailRepository) ReplacePendingPrimary(userId int, oldEmailId int, newAddress string) (*models.Email, error) {
+
+	tx := r.DB.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	deleteResult := tx.Where("id = ? AND user_id = ?", oldEmailId, userId).Delete(&models.Email{})
+	if deleteResult.Error != nil {
+		tx.Rollback()
+		return nil, deleteResult.Error
+	}
+
+	// oldEmailId is stale (already replaced/deleted by a racing call) -
+	// bail out instead of inserting a second primary.
+	if deleteResult.RowsAffected != 1 {
+		tx.Rollback()
+		return nil, errors.NewToUser("That email is no longer your pending primary, please retry.")
+	}
+
+	newEmail := &models.Email{
+		UserId:     userId,
+		Email:      newAddress,
+		IsPrimary:  true,
+		IsVerified: false,
+	}
+
+	if err := tx.Create(newEmail).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return newEmail, nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gocms-io/gocms/models"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func newTestEmailRepository(t *testing.T) *EmailRepository {
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("opening test db: %s", err.Error())
+	}
+
+	// A single connection with a busy timeout makes concurrent
+	// transactions queue and serialize on sqlite's write lock instead of
+	// failing outright with "database table is locked", so tests that
+	// race two transactions actually exercise the delete-then-insert
+	// ordering rather than just one transaction erroring immediately.
+	db.DB().SetMaxOpenConns(1)
+	if err := db.Exec("PRAGMA busy_timeout = 5000").Error; err != nil {
+		t.Fatalf("setting busy_timeout: %s", err.Error())
+	}
+
+	if err := db.AutoMigrate(&models.Email{}).Error; err != nil {
+		t.Fatalf("migrating test db: %s", err.Error())
+	}
+
+	return &EmailRepository{DB: db}
+}
+
+// TestReplacePendingPrimaryStaleOldEmailIdIsRejected directly exercises the
+// race this function exists to prevent: a second call against an
+// oldEmailId a prior call already replaced must fail instead of inserting
+// a second primary.
+func TestReplacePendingPrimaryStaleOldEmailIdIsRejected(t *testing.T) {
+	repo := newTestEmailRepository(t)
+
+	userId := 1
+	original := &models.Email{UserId: userId, Email: "typo@examlpe.com", IsPrimary: true, IsVerified: false}
+	if err := repo.DB.Create(original).Error; err != nil {
+		t.Fatalf("seeding original email: %s", err.Error())
+	}
+
+	if _, err := repo.ReplacePendingPrimary(userId, original.Id, "fixed-a@example.com"); err != nil {
+		t.Fatalf("first replace: %s", err.Error())
+	}
+
+	if _, err := repo.ReplacePendingPrimary(userId, original.Id, "fixed-b@example.com"); err == nil {
+		t.Error("expected second replace against a stale oldEmailId to fail, got nil error")
+	}
+
+	assertExactlyOnePrimary(t, repo, userId)
+}
+
+// TestReplacePendingPrimaryConcurrentCallsDontDuplicatePrimary fires two
+// concurrent ReplacePendingPrimary calls against the same pending primary
+// and asserts the user never ends up with more than one primary email:
+// whichever transaction commits first deletes the only row matching
+// oldEmailId, so the loser's delete affects zero rows and is rejected
+// before it can insert a second primary.
+func TestReplacePendingPrimaryConcurrentCallsDontDuplicatePrimary(t *testing.T) {
+	repo := newTestEmailRepository(t)
+
+	userId := 1
+	original := &models.Email{UserId: userId, Email: "typo@examlpe.com", IsPrimary: true, IsVerified: false}
+	if err := repo.DB.Create(original).Error; err != nil {
+		t.Fatalf("seeding original email: %s", err.Error())
+	}
+
+	var wg sync.WaitGroup
+	addresses := []string{"fixed-a@example.com", "fixed-b@example.com"}
+
+	for _, addr := range addresses {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			_, _ = repo.ReplacePendingPrimary(userId, original.Id, addr)
+		}(addr)
+	}
+	wg.Wait()
+
+	assertExactlyOnePrimary(t, repo, userId)
+}
+
+func assertExactlyOnePrimary(t *testing.T, repo *EmailRepository, userId int) {
+	t.Helper()
+
+	var primaries []models.Email
+	if err := repo.DB.Where("user_id = ? AND is_primary = ?", userId, true).Find(&primaries).Error; err != nil {
+		t.Fatalf("querying primaries: %s", err.Error())
+	}
+
+	if len(primaries) != 1 {
+		t.Errorf("expected exactly one primary email, got %d", len(primaries))
+	}
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import "github.com/jinzhu/gorm"
+
+// RepositoriesGroup is the single collection of repositories services are
+// constructed with, so adding a new repository only means adding a field
+// here and a line in DefaultRepositoriesGroup, not threading a new
+// constructor argument through every service.
+type RepositoriesGroup struct {
+	EmailRepository                   *EmailRepository
+	SecureCodeRepository              *SecureCodeRepository
+	EmailActivationThrottleRepository *EmailActivationThrottleRepository
+	EmailAuditRepository              *EmailAuditRepository
+}
+
+func DefaultRepositoriesGroup(db *gorm.DB) *RepositoriesGroup {
+	return &RepositoriesGroup{
+		EmailRepository:                   DefaultEmailRepository(db),
+		SecureCodeRepository:              DefaultSecureCodeRepository(db),
+		EmailActivationThrottleRepository: DefaultEmailActivationThrottleRepository(db),
+		EmailAuditRepository:              DefaultEmailAuditRepository(db),
+	}
+}
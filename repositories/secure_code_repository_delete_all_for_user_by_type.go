@@ -0,0 +1,10 @@
+package repositories
+
+import "github.com/gocms-io/gocms/models"
+
+// DeleteAllForUserByType invalidates every outstanding secure code of the
+// given type for a user, e.g. to cut off an old email activation code once
+// the pending address it was issued for has been replaced.
+func (r *SecureCodeRepository) DeleteAllForUserByType(userId int, codeType models.SecureCodeType) error {
+	return r.DB.Where("user_id = ? AND type = ?", userId, codeType).Delete(&models.SecureCode{}).Error
+}
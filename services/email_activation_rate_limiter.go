@@ -0,0 +1,85 @@
+package services
+
+import (
+	"time"
+
+	"github.com/gocms-io/gocms/context"
+)
+
+// ErrTooManyActivationRequests is returned when a caller has exceeded the
+// resend cooldown or the hourly cap for activation emails. RetryAfter is
+// how long until the limit that rejected the request stops applying; when
+// the cooldown is what rejected it, the hourly cap is not taken into
+// account.
+type ErrTooManyActivationRequests struct {
+	RetryAfter time.Duration
+}
+
+func (e *ErrTooManyActivationRequests) Error() string {
+	return "too many activation code requests, retry after " + e.RetryAfter.String()
+}
+
+// activationRateLimitBuckets picks the cooldown/hourly cap pair for
+// (userId, emailAddress): steady-state limits once the account has a
+// verified email, a more permissive pair before that so a user fixing a
+// typo'd signup address isn't stuck behind them.
+func activationRateLimitBuckets(accountHasVerifiedEmail bool) (cooldown time.Duration, hourlyCap int) {
+	if !accountHasVerifiedEmail {
+		return time.Duration(context.Config.ActivationResendCooldownPreVerificationSeconds) * time.Second,
+			context.Config.ActivationHourlyCapPreVerification
+	}
+	return time.Duration(context.Config.ActivationResendCooldownMinutes) * time.Minute,
+		context.Config.ActivationHourlyCap
+}
+
+// checkActivationRateLimit is a read-only, best-effort pre-check used
+// where we need to reject an over-the-limit caller before doing unrelated
+// work (e.g. before ReplacePendingPrimary mutates the primary row). It does
+// not record a send, so it does not by itself close the check-then-write
+// race; checkAndRecordActivationSend does that for the actual send path.
+func (es *EmailService) checkActivationRateLimit(userId int, emailAddress string, accountHasVerifiedEmail bool) error {
+
+	cooldown, hourlyCap := activationRateLimitBuckets(accountHasVerifiedEmail)
+
+	throttle, err := es.RepositoriesGroup.EmailActivationThrottleRepository.GetByUserAndEmail(userId, emailAddress)
+	if err != nil {
+		// no record yet, nothing to throttle
+		return nil
+	}
+
+	now := time.Now()
+
+	if since := now.Sub(throttle.LastSentAt); since < cooldown {
+		return &ErrTooManyActivationRequests{RetryAfter: cooldown - since}
+	}
+
+	windowElapsed := now.Sub(throttle.WindowStart)
+	if windowElapsed < time.Hour && throttle.CountInWindow >= hourlyCap {
+		return &ErrTooManyActivationRequests{RetryAfter: time.Hour - windowElapsed}
+	}
+
+	return nil
+}
+
+// checkAndRecordActivationSend enforces the rate limit and, if the send is
+// allowed, records it, both within the single repository transaction
+// CheckAndRecordSend runs under a row lock. This is what
+// SendEmailActivationCode uses so concurrent resend-button mashing for the
+// same (userId, emailAddress) can't all pass the check before any of them
+// records a send.
+func (es *EmailService) checkAndRecordActivationSend(userId int, emailAddress string, accountHasVerifiedEmail bool) error {
+
+	cooldown, hourlyCap := activationRateLimitBuckets(accountHasVerifiedEmail)
+
+	allowed, retryAfter, err := es.RepositoriesGroup.EmailActivationThrottleRepository.
+		CheckAndRecordSend(userId, emailAddress, cooldown, hourlyCap, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return &ErrTooManyActivationRequests{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
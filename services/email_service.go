@@ -17,13 +17,18 @@ type IEmailService interface {
 	GetEmailsByUserId(userId int) ([]models.Email, error)
 	SendEmailActivationCode(email string) error
 	VerifyEmailActivationCode(id int, code string) bool
+	ReplacePendingPrimary(userId int, newAddress string) error
 	PromoteEmail(email *models.Email) error
 	DeleteEmail(email *models.Email) error
+	AdminSetVerified(adminUserId int, targetEmailId int, verified bool) error
+	AdminListEmails(adminUserId int, filter repositories.EmailFilter) ([]models.Email, error)
+	AdminForceDelete(adminUserId int, emailId int) error
 }
 
 type EmailService struct {
 	MailService       IMailService
 	AuthService       IAuthService
+	MailRenderer      IMailRenderer
 	RepositoriesGroup *repositories.RepositoriesGroup
 }
 
@@ -32,6 +37,7 @@ func DefaultEmailService(rg *repositories.RepositoriesGroup, ms *MailService, as
 		RepositoriesGroup: rg,
 		AuthService:       as,
 		MailService:       ms,
+		MailRenderer:      DefaultMailRenderer(),
 	}
 	return emailService
 }
@@ -64,6 +70,11 @@ func (es *EmailService) GetVerified(e string) bool {
 
 func (es *EmailService) AddEmail(e *models.Email) error {
 
+	// validate address before it ever reaches the repository layer
+	if err := validateEmailAddress(e.Email); err != nil {
+		return err
+	}
+
 	// check to see if email exist
 	emailExists, _ := es.RepositoriesGroup.EmailRepository.GetByAddress(e.Email)
 	if emailExists != nil {
@@ -79,12 +90,13 @@ func (es *EmailService) AddEmail(e *models.Email) error {
 
 	// send email to primary email about addition of email
 	if primaryEmail, err := es.RepositoriesGroup.EmailRepository.GetPrimaryByUserId(e.UserId); err == nil {
-		mail := Mail{
-			To:      primaryEmail.Email,
-			Subject: "New Email Added To Your Account",
-			Body:    "A new alternative email address, " + e.Email + ", was added to your account.\n\n If you believe this to be a mistake please contact support.",
+		mail, err := es.MailRenderer.Render("email/added.tmpl", emailAddedTemplateData{Email: e.Email})
+		if err != nil {
+			log.Printf("email service, add email, rendering notification, error: %s", err.Error())
+			return nil
 		}
-		es.MailService.Send(&mail)
+		mail.To = primaryEmail.Email
+		es.MailService.Send(mail)
 	}
 
 	return nil
@@ -92,6 +104,11 @@ func (es *EmailService) AddEmail(e *models.Email) error {
 
 func (es *EmailService) SendEmailActivationCode(emailAddress string) error {
 
+	// validate address before it ever reaches the repository layer
+	if err := validateEmailAddress(emailAddress); err != nil {
+		return err
+	}
+
 	// get userId from email
 	email, err := es.RepositoriesGroup.EmailRepository.GetByAddress(emailAddress)
 	if err != nil {
@@ -105,6 +122,11 @@ func (es *EmailService) SendEmailActivationCode(emailAddress string) error {
 		return err
 	}
 
+	if err := es.checkAndRecordActivationSend(email.UserId, emailAddress, es.userHasAnyVerifiedEmail(email.UserId)); err != nil {
+		fmt.Printf("Error sending email activation code, rate limited: %s\n", err.Error())
+		return err
+	}
+
 	// create reset code
 	code, hashedCode, err := es.AuthService.GetRandomCode(32)
 	if err != nil {
@@ -124,20 +146,39 @@ func (es *EmailService) SendEmailActivationCode(emailAddress string) error {
 	}
 
 	// send email
-	es.MailService.Send(&Mail{
-		To:      emailAddress,
-		Subject: "Email Verification Required",
-		Body: "Click on the link below to activate your email:\n" +
-			context.Config.PublicApiUrl + "/user/email/activate?code=" + code + "&email=" + emailAddress + "\n\nThe link will expire at: " +
-			time.Now().Add(time.Minute*time.Duration(context.Config.PasswordResetTimeout)).String() + ".",
+	mail, err := es.MailRenderer.Render("email/activation.tmpl", emailActivationTemplateData{
+		Email:     emailAddress,
+		Code:      code,
+		ExpiresAt: time.Now().Add(time.Minute * time.Duration(context.Config.PasswordResetTimeout)),
 	})
 	if err != nil {
-		log.Println("Error sending email activation code, sending mail: " + err.Error())
+		log.Println("Error sending email activation code, rendering mail: " + err.Error())
+		return err
 	}
+	mail.To = emailAddress
+	es.MailService.Send(mail)
 
 	return nil
 }
 
+// userHasAnyVerifiedEmail reports whether userId has at least one verified
+// email on file, used to select the more permissive pre-verification
+// activation rate limit.
+func (es *EmailService) userHasAnyVerifiedEmail(userId int) bool {
+	emails, err := es.RepositoriesGroup.EmailRepository.GetByUserId(userId)
+	if err != nil {
+		return false
+	}
+
+	for _, e := range emails {
+		if e.IsVerified {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (es *EmailService) VerifyEmailActivationCode(id int, code string) bool {
 
 	// get code
@@ -164,6 +205,55 @@ func (es *EmailService) VerifyEmailActivationCode(id int, code string) bool {
 	return true
 }
 
+// ReplacePendingPrimary lets a user with no verified email on file correct
+// a typo'd signup address. It is only valid while the account has zero
+// verified emails: it atomically deletes the current unverified primary
+// and inserts newAddress as primary+unverified, invalidates every
+// outstanding email activation code on the account, and sends a fresh
+// activation code to newAddress.
+func (es *EmailService) ReplacePendingPrimary(userId int, newAddress string) error {
+
+	if err := validateEmailAddress(newAddress); err != nil {
+		return err
+	}
+
+	if es.userHasAnyVerifiedEmail(userId) {
+		err := errors.NewToUser("You already have a verified email address on your account.")
+		log.Printf("email service, replace pending primary, error: %s", err.Error())
+		return err
+	}
+
+	// enforce the rate limit before mutating anything: a caller that's
+	// already cooling down must not get their primary row swapped
+	// regardless of whether the activation email itself ends up sent
+	if err := es.checkActivationRateLimit(userId, newAddress, false); err != nil {
+		log.Printf("email service, replace pending primary, rate limited: %s", err.Error())
+		return err
+	}
+
+	oldPrimary, err := es.RepositoriesGroup.EmailRepository.GetPrimaryByUserId(userId)
+	if err != nil {
+		log.Printf("email service, replace pending primary, get primary by userId, error: %s", err.Error())
+		return err
+	}
+
+	if _, err := es.RepositoriesGroup.EmailRepository.ReplacePendingPrimary(userId, oldPrimary.Id, newAddress); err != nil {
+		log.Printf("email service, replace pending primary, replacing, error: %s", err.Error())
+		return err
+	}
+
+	if err := es.RepositoriesGroup.SecureCodeRepository.DeleteAllForUserByType(userId, models.Code_VerifyEmail); err != nil {
+		log.Printf("email service, replace pending primary, invalidating old codes, error: %s", err.Error())
+	}
+
+	if err := es.SendEmailActivationCode(newAddress); err != nil {
+		log.Printf("email service, replace pending primary, sending activation code, error: %s", err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (es *EmailService) PromoteEmail(email *models.Email) error {
 
 	// get email for verification
@@ -202,13 +292,13 @@ func (es *EmailService) PromoteEmail(email *models.Email) error {
 	}
 
 	// send notification
-	// send email to primary email about addition of email
-	mail := Mail{
-		To:      oldPrimaryEmail.Email,
-		Subject: "A New Primary Email Has Been Set",
-		Body:    "A new primary email address, " + email.Email + ", has been set on your account.\n\n If you believe this to be a mistake please contact support.",
+	mail, err := es.MailRenderer.Render("email/promoted.tmpl", emailPromotedTemplateData{Email: email.Email})
+	if err != nil {
+		log.Printf("email service, promote email, rendering notification, error: %s", err.Error())
+		return nil
 	}
-	es.MailService.Send(&mail)
+	mail.To = oldPrimaryEmail.Email
+	es.MailService.Send(mail)
 
 	return nil
 }
@@ -263,13 +353,13 @@ func (es *EmailService) DeleteEmail(email *models.Email) error {
 	}
 
 	// send notification
-	// send email to primary email about addition of email
-	mail := Mail{
-		To:      primaryEmail.Email,
-		Subject: "Alternative Email Delete From Account",
-		Body:    "An alternative email, " + email.Email + ", has been deleted from your account.\n\n If you believe this to be a mistake please contact support.",
+	mail, err := es.MailRenderer.Render("email/deleted.tmpl", emailDeletedTemplateData{Email: email.Email})
+	if err != nil {
+		log.Printf("email service, delete email, rendering notification, error: %s", err.Error())
+		return nil
 	}
-	es.MailService.Send(&mail)
+	mail.To = primaryEmail.Email
+	es.MailService.Send(mail)
 
 	return nil
 }
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gocms-io/gocms/models"
+	"github.com/gocms-io/gocms/repositories"
+	"github.com/gocms-io/gocms/utility/errors"
+)
+
+// AdminSetVerified marks targetEmailId as verified or unverified on behalf
+// of an administrator, e.g. to unstick a user whose activation email
+// bounced or was sent to the wrong address.
+func (es *EmailService) AdminSetVerified(adminUserId int, targetEmailId int, verified bool) error {
+
+	if !es.AuthService.IsAdmin(adminUserId) {
+		return errors.NewToUser("You do not have permission to perform this action.")
+	}
+
+	email, err := es.RepositoriesGroup.EmailRepository.GetById(targetEmailId)
+	if err != nil {
+		log.Printf("email service, admin set verified, get by id, error: %s", err.Error())
+		return err
+	}
+
+	before := fmt.Sprintf("verified=%t", email.IsVerified)
+	email.IsVerified = verified
+
+	if err := es.RepositoriesGroup.EmailRepository.Update(email); err != nil {
+		log.Printf("email service, admin set verified, update, error: %s", err.Error())
+		return err
+	}
+
+	es.auditAdminAction(adminUserId, email.Id, "set_verified", before, fmt.Sprintf("verified=%t", email.IsVerified))
+	es.notifyAdminAction(email.UserId, "Your email verification status was updated by an administrator.")
+
+	return nil
+}
+
+// AdminListEmails returns every Email matching filter for administrative
+// tooling. There is no single target email to audit or notify about here,
+// but the admin role check still applies like it does for AdminSetVerified
+// and AdminForceDelete.
+func (es *EmailService) AdminListEmails(adminUserId int, filter repositories.EmailFilter) ([]models.Email, error) {
+	if !es.AuthService.IsAdmin(adminUserId) {
+		return nil, errors.NewToUser("You do not have permission to perform this action.")
+	}
+
+	return es.RepositoriesGroup.EmailRepository.Find(filter)
+}
+
+// AdminForceDelete removes emailId even if it is the account's primary,
+// provided the account has another verified email to promote in its
+// place. This unblocks support workflows where a user's primary address
+// is no longer reachable.
+func (es *EmailService) AdminForceDelete(adminUserId int, emailId int) error {
+
+	if !es.AuthService.IsAdmin(adminUserId) {
+		return errors.NewToUser("You do not have permission to perform this action.")
+	}
+
+	target, err := es.RepositoriesGroup.EmailRepository.GetById(emailId)
+	if err != nil {
+		log.Printf("email service, admin force delete, get by id, error: %s", err.Error())
+		return err
+	}
+
+	if target.IsPrimary {
+		replacement, err := es.findVerifiedReplacementPrimary(target.UserId, target.Id)
+		if err != nil {
+			return errors.NewToUser("That email can't be removed: the account has no other verified email to promote in its place.")
+		}
+		if err := es.RepositoriesGroup.EmailRepository.PromoteEmail(replacement.Id, replacement.UserId); err != nil {
+			log.Printf("email service, admin force delete, promoting replacement, error: %s", err.Error())
+			return err
+		}
+	}
+
+	before := fmt.Sprintf("email=%s verified=%t primary=%t", target.Email, target.IsVerified, target.IsPrimary)
+
+	if err := es.RepositoriesGroup.EmailRepository.Delete(target.Id); err != nil {
+		log.Printf("email service, admin force delete, delete, error: %s", err.Error())
+		return err
+	}
+
+	es.auditAdminAction(adminUserId, target.Id, "force_delete", before, "deleted")
+	es.notifyAdminAction(target.UserId, fmt.Sprintf("Your email address %s was removed from your account by an administrator.", target.Email))
+
+	return nil
+}
+
+// findVerifiedReplacementPrimary returns a verified email belonging to
+// userId other than excludeEmailId, or an error if none exists.
+func (es *EmailService) findVerifiedReplacementPrimary(userId int, excludeEmailId int) (*models.Email, error) {
+	emails, err := es.RepositoriesGroup.EmailRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range emails {
+		if e.Id != excludeEmailId && e.IsVerified {
+			replacement := e
+			return &replacement, nil
+		}
+	}
+
+	return nil, errors.NewToUser("No verified replacement email available.")
+}
+
+// auditAdminAction records an administrative action against an Email so
+// support operations remain traceable.
+func (es *EmailService) auditAdminAction(actorUserId int, targetEmailId int, action string, before string, after string) {
+	err := es.RepositoriesGroup.EmailAuditRepository.Add(&models.EmailAudit{
+		ActorUserId:   actorUserId,
+		TargetEmailId: targetEmailId,
+		Action:        action,
+		BeforeState:   before,
+		AfterState:    after,
+	})
+	if err != nil {
+		log.Printf("email service, admin action, writing audit record, error: %s", err.Error())
+	}
+}
+
+// notifyAdminAction sends a courtesy notification to targetUserId's
+// primary email about an administrative action taken on their account.
+func (es *EmailService) notifyAdminAction(targetUserId int, message string) {
+	primaryEmail, err := es.RepositoriesGroup.EmailRepository.GetPrimaryByUserId(targetUserId)
+	if err != nil {
+		log.Printf("email service, admin action, get primary by userId, error: %s", err.Error())
+		return
+	}
+
+	mail, err := es.MailRenderer.Render("email/admin_notice.tmpl", emailAdminNoticeTemplateData{Message: message})
+	if err != nil {
+		log.Printf("email service, admin action, rendering notification, error: %s", err.Error())
+		return
+	}
+
+	mail.To = primaryEmail.Email
+	es.MailService.Send(mail)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gocms-io/gocms/context"
+	"github.com/gocms-io/gocms/repositories"
+	"github.com/gocms-io/gocms/utility/errors"
+)
+
+var (
+	ErrEmailInvalid          = errors.NewToUser("That email address is not valid.")
+	ErrEmailCharNotSupported = errors.NewToUser("That email address contains unsupported characters.")
+	ErrEmailDomainBlocked    = errors.NewToUser("That email domain is not allowed.")
+)
+
+// maxEmailLength mirrors the practical limit used by most mail transfer
+// agents and by RFC 3696 errata (254 bytes including the "@").
+const maxEmailLength = 254
+
+// Default safe character sets, used whenever EmailCharsetPolicy leaves the
+// corresponding field empty: letters, digits, and "._-+" in the local
+// part; letters, digits, and ".-" in the domain.
+const (
+	defaultEmailLocalPartPattern = `^[a-zA-Z0-9._+-]+$`
+	defaultEmailDomainPattern    = `^[a-zA-Z0-9.-]+$`
+)
+
+// EmailCharsetPolicy lets an operator override the "safe" character set
+// validateEmailAddress enforces, as an alternative to the conservative
+// defaults above. Either field may be left empty to keep its default.
+type EmailCharsetPolicy struct {
+	LocalPartPattern string
+	DomainPattern    string
+}
+
+var regexCache sync.Map // pattern string -> *regexp.Regexp
+
+func compiledPattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Store(pattern, compiled)
+	return compiled, nil
+}
+
+func emailLocalPartPattern() (*regexp.Regexp, error) {
+	pattern := context.Config.EmailCharsetPolicy.LocalPartPattern
+	if pattern == "" {
+		pattern = defaultEmailLocalPartPattern
+	}
+	return compiledPattern(pattern)
+}
+
+func emailDomainPattern() (*regexp.Regexp, error) {
+	pattern := context.Config.EmailCharsetPolicy.DomainPattern
+	if pattern == "" {
+		pattern = defaultEmailDomainPattern
+	}
+	return compiledPattern(pattern)
+}
+
+// EmailDomainPolicy restricts which domains may be used for account email
+// addresses. BlockedDomains is checked first so an operator can carve a
+// disposable-provider exception out of an otherwise open policy. An empty
+// AllowedDomains allows any domain that isn't blocked; a non-empty
+// AllowedDomains requires a match. Entries may use a single leading "*."
+// wildcard segment, e.g. "*.example.com".
+type EmailDomainPolicy struct {
+	AllowedDomains []string
+	BlockedDomains []string
+}
+
+// validateEmailAddress is the single gate AddEmail and
+// SendEmailActivationCode run every address through before it reaches the
+// repository layer. It checks the 254 byte length limit, RFC 5322
+// conformance, a leading "-" in either half, a conservative character
+// allow-list, and the configured EmailDomainPolicy, in that order.
+func validateEmailAddress(address string) error {
+
+	if len(address) == 0 || len(address) > maxEmailLength {
+		return ErrEmailInvalid
+	}
+
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return ErrEmailInvalid
+	}
+
+	local, domain, ok := splitEmailAddress(parsed.Address)
+	if !ok {
+		return ErrEmailInvalid
+	}
+
+	if strings.HasPrefix(local, "-") || strings.HasPrefix(domain, "-") {
+		return ErrEmailInvalid
+	}
+
+	localPattern, err := emailLocalPartPattern()
+	if err != nil {
+		return ErrEmailCharNotSupported
+	}
+
+	domainPattern, err := emailDomainPattern()
+	if err != nil {
+		return ErrEmailCharNotSupported
+	}
+
+	if !localPattern.MatchString(local) || !domainPattern.MatchString(domain) {
+		return ErrEmailCharNotSupported
+	}
+
+	if !checkEmailDomainPolicy(domain, context.Config.EmailDomainPolicy) {
+		return ErrEmailDomainBlocked
+	}
+
+	return nil
+}
+
+// splitEmailAddress splits on the last "@" so local parts containing an
+// escaped "@" (permitted by net/mail's parsing) don't shift the domain.
+func splitEmailAddress(address string) (local string, domain string, ok bool) {
+	at := strings.LastIndex(address, "@")
+	if at < 1 || at == len(address)-1 {
+		return "", "", false
+	}
+	return address[:at], address[at+1:], true
+}
+
+func checkEmailDomainPolicy(domain string, policy EmailDomainPolicy) bool {
+	domain = strings.ToLower(domain)
+
+	for _, blocked := range policy.BlockedDomains {
+		if repositories.DomainGlobMatch(blocked, domain) {
+			return false
+		}
+	}
+
+	if len(policy.AllowedDomains) == 0 {
+		return true
+	}
+
+	for _, allowed := range policy.AllowedDomains {
+		if repositories.DomainGlobMatch(allowed, domain) {
+			return true
+		}
+	}
+
+	return false
+}
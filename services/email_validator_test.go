@@ -0,0 +1,112 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gocms-io/gocms/context"
+)
+
+func TestValidateEmailAddress(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		address string
+		wantErr error
+	}{
+		{"simple valid", "user@example.com", nil},
+		{"plus addressing", "user+newsletter@example.com", nil},
+		{"subdomain", "user@mail.example.com", nil},
+		{"punycode IDN domain", "user@xn--mnchen-3ya.de", nil},
+		{"raw unicode domain rejected", "user@münchen.de", ErrEmailCharNotSupported},
+		{"empty", "", ErrEmailInvalid},
+		{"missing at", "userexample.com", ErrEmailInvalid},
+		{"leading dash local", "-user@example.com", ErrEmailInvalid},
+		{"leading dash domain", "user@-example.com", ErrEmailInvalid},
+		{"space in local part fails RFC parse", "us er@example.com", ErrEmailInvalid},
+		{"too long", "user@" + strings.Repeat("a", 250) + ".com", ErrEmailInvalid},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateEmailAddress(c.address)
+			if err != c.wantErr {
+				t.Errorf("validateEmailAddress(%q) = %v, want %v", c.address, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestEmailDomainPolicy(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		policy EmailDomainPolicy
+		domain string
+		want   bool
+	}{
+		{"no policy allows all", EmailDomainPolicy{}, "example.com", true},
+		{"allowed exact match", EmailDomainPolicy{AllowedDomains: []string{"example.com"}}, "example.com", true},
+		{"allowed no match", EmailDomainPolicy{AllowedDomains: []string{"example.com"}}, "evil.com", false},
+		{"allowed wildcard", EmailDomainPolicy{AllowedDomains: []string{"*.example.com"}}, "mail.example.com", true},
+		{"blocked wins over allowed", EmailDomainPolicy{
+			AllowedDomains: []string{"*.example.com"},
+			BlockedDomains: []string{"spam.example.com"},
+		}, "spam.example.com", false},
+		{"blocked only", EmailDomainPolicy{BlockedDomains: []string{"mailinator.com"}}, "mailinator.com", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := checkEmailDomainPolicy(c.domain, c.policy)
+			if got != c.want {
+				t.Errorf("checkEmailDomainPolicy(%q) = %v, want %v", c.domain, got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateEmailAddressRespectsDomainPolicy(t *testing.T) {
+	original := context.Config.EmailDomainPolicy
+	defer func() { context.Config.EmailDomainPolicy = original }()
+
+	context.Config.EmailDomainPolicy = EmailDomainPolicy{
+		AllowedDomains: []string{"*.corp.example.com"},
+		BlockedDomains: []string{"blocked.corp.example.com"},
+	}
+
+	if err := validateEmailAddress("user@team.corp.example.com"); err != nil {
+		t.Errorf("expected allowed domain to pass, got %v", err)
+	}
+
+	if err := validateEmailAddress("user@blocked.corp.example.com"); err != ErrEmailDomainBlocked {
+		t.Errorf("expected ErrEmailDomainBlocked, got %v", err)
+	}
+
+	if err := validateEmailAddress("user@outside.com"); err != ErrEmailDomainBlocked {
+		t.Errorf("expected ErrEmailDomainBlocked for domain outside allow list, got %v", err)
+	}
+}
+
+func TestValidateEmailAddressRespectsConfigurableCharset(t *testing.T) {
+	original := context.Config.EmailCharsetPolicy
+	defer func() { context.Config.EmailCharsetPolicy = original }()
+
+	// An operator who wants to permit "=" in the local part (some legacy
+	// mail systems generate addresses like "user=tag@example.com") can do
+	// so without a code change.
+	context.Config.EmailCharsetPolicy = EmailCharsetPolicy{
+		LocalPartPattern: `^[a-zA-Z0-9._+=-]+$`,
+	}
+
+	if err := validateEmailAddress("user=tag@example.com"); err != nil {
+		t.Errorf("expected custom charset policy to allow '=', got %v", err)
+	}
+
+	// Reverting to the empty policy restores the default, stricter set.
+	context.Config.EmailCharsetPolicy = EmailCharsetPolicy{}
+
+	if err := validateEmailAddress("user=tag@example.com"); err != ErrEmailCharNotSupported {
+		t.Errorf("expected default charset policy to reject '=', got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package services
+
+// Mail is a single outgoing notification message, handed from a renderer
+// (e.g. MailRenderer) to MailService.Send. ContentType is the MIME
+// Content-Type the message should be sent with; callers that set it to a
+// multipart type are responsible for Body already being a valid MIME body
+// for that boundary (see buildMultipartAlternative).
+type Mail struct {
+	To          string
+	Subject     string
+	Body        string
+	ContentType string
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"bytes"
+	"html/template"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	textTemplate "text/template"
+	"time"
+
+	"github.com/gocms-io/gocms/context"
+)
+
+// Template data for the built-in notification templates shipped under
+// templates/email/. Each EmailService notification passes one of these to
+// MailRenderer.Render.
+type emailAddedTemplateData struct {
+	Email string
+}
+
+type emailPromotedTemplateData struct {
+	Email string
+}
+
+type emailDeletedTemplateData struct {
+	Email string
+}
+
+type emailActivationTemplateData struct {
+	Email     string
+	Code      string
+	ExpiresAt time.Time
+}
+
+type emailAdminNoticeTemplateData struct {
+	Message string
+}
+
+// IMailRenderer renders a named notification template into a ready-to-send
+// Mail carrying both text/plain and text/html MIME alternatives.
+type IMailRenderer interface {
+	Render(name string, data interface{}) (*Mail, error)
+}
+
+// MailRenderer loads templates from TemplatesDir, the set shipped with the
+// application, falling back from an optional CustomDir overlay that lets an
+// operator restyle or relocalize notifications without touching the
+// binary. A template file is expected to define three named blocks:
+// "subject", "text", and "html".
+type MailRenderer struct {
+	TemplatesDir string
+	CustomDir    string
+}
+
+func DefaultMailRenderer() *MailRenderer {
+	return &MailRenderer{
+		TemplatesDir: context.Config.EmailTemplatesDir,
+		CustomDir:    context.Config.EmailTemplatesCustomDir,
+	}
+}
+
+// Render loads name (e.g. "email/added.tmpl"), preferring CustomDir over
+// TemplatesDir, and executes its "subject", "text", and "html" blocks
+// against data, combining the text and html bodies into a single
+// multipart/alternative message.
+func (mr *MailRenderer) Render(name string, data interface{}) (*Mail, error) {
+
+	path := mr.resolveTemplatePath(name)
+
+	subject, err := mr.renderTextBlock(path, "subject", data)
+	if err != nil {
+		return nil, err
+	}
+
+	textBody, err := mr.renderTextBlock(path, "text", data)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlBody, err := mr.renderHtmlBlock(path, data)
+	if err != nil {
+		return nil, err
+	}
+
+	body, contentType, err := buildMultipartAlternative(textBody, htmlBody)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Mail{
+		Subject:     strings.TrimSpace(subject),
+		Body:        body,
+		ContentType: contentType,
+	}, nil
+}
+
+// resolveTemplatePath prefers the custom overlay directory, falling back
+// to the built-in template if no override is present.
+func (mr *MailRenderer) resolveTemplatePath(name string) string {
+	if mr.CustomDir != "" {
+		customPath := filepath.Join(mr.CustomDir, name)
+		if _, err := os.Stat(customPath); err == nil {
+			return customPath
+		}
+	}
+	return filepath.Join(mr.TemplatesDir, name)
+}
+
+func (mr *MailRenderer) renderTextBlock(path string, block string, data interface{}) (string, error) {
+	tmpl, err := textTemplate.New(filepath.Base(path)).Funcs(mailTemplateFuncMap()).ParseFiles(path)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, block, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (mr *MailRenderer) renderHtmlBlock(path string, data interface{}) (string, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(mailTemplateFuncMap()).ParseFiles(path)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "html", data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// mailTemplateFuncMap is shared by both the text/template and html/template
+// engines: it exposes the current time, date formatting, the password
+// reset timeout from config, and the public application URL that templates
+// commonly need to link back to.
+func mailTemplateFuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"now": time.Now,
+		"formatDate": func(t time.Time, layout string) string {
+			return t.Format(layout)
+		},
+		"AppUrl": func() string {
+			return context.Config.PublicApiUrl
+		},
+		"passwordResetTimeoutMinutes": func() int {
+			return context.Config.PasswordResetTimeout
+		},
+	}
+}
+
+// buildMultipartAlternative combines a text and an html rendering into a
+// single multipart/alternative MIME body and returns the Content-Type
+// header value (boundary included) that body must be sent with. The
+// caller is responsible for setting that value on Mail.ContentType rather
+// than folding it into Body: MailService.Send sets the message's
+// Content-Type header from ContentType, so a body without it attached
+// would be indistinguishable from plain text to the recipient's client.
+func buildMultipartAlternative(textBody string, htmlBody string) (body string, contentType string, err error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	textPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=UTF-8"}})
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := textPart.Write([]byte(textBody)); err != nil {
+		return "", "", err
+	}
+
+	htmlPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := htmlPart.Write([]byte(htmlBody)); err != nil {
+		return "", "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+
+	return buf.String(), "multipart/alternative; boundary=" + writer.Boundary(), nil
+}
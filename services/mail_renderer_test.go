@@ -0,0 +1,101 @@
+package services
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMailRenderer() *MailRenderer {
+	return &MailRenderer{TemplatesDir: "../templates"}
+}
+
+func TestMailRendererRendersAddedTemplate(t *testing.T) {
+	mail, err := testMailRenderer().Render("email/added.tmpl", emailAddedTemplateData{Email: "alt@example.com"})
+	if err != nil {
+		t.Fatalf("rendering added template: %s", err.Error())
+	}
+	if !strings.Contains(mail.Subject, "New Email Added") {
+		t.Errorf("unexpected subject: %q", mail.Subject)
+	}
+	if !strings.Contains(mail.Body, "alt@example.com") {
+		t.Errorf("expected body to mention the added address, got: %q", mail.Body)
+	}
+	if !strings.Contains(mail.ContentType, "multipart/alternative") {
+		t.Errorf("expected a multipart/alternative content type, got: %q", mail.ContentType)
+	}
+}
+
+func TestMailRendererRendersPromotedTemplate(t *testing.T) {
+	mail, err := testMailRenderer().Render("email/promoted.tmpl", emailPromotedTemplateData{Email: "new-primary@example.com"})
+	if err != nil {
+		t.Fatalf("rendering promoted template: %s", err.Error())
+	}
+	if !strings.Contains(mail.Body, "new-primary@example.com") {
+		t.Errorf("expected body to mention the promoted address, got: %q", mail.Body)
+	}
+}
+
+func TestMailRendererRendersDeletedTemplate(t *testing.T) {
+	mail, err := testMailRenderer().Render("email/deleted.tmpl", emailDeletedTemplateData{Email: "old@example.com"})
+	if err != nil {
+		t.Fatalf("rendering deleted template: %s", err.Error())
+	}
+	if !strings.Contains(mail.Body, "old@example.com") {
+		t.Errorf("expected body to mention the deleted address, got: %q", mail.Body)
+	}
+}
+
+func TestMailRendererRendersActivationTemplate(t *testing.T) {
+	mail, err := testMailRenderer().Render("email/activation.tmpl", emailActivationTemplateData{
+		Email:     "pending@example.com",
+		Code:      "abc123",
+		ExpiresAt: time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("rendering activation template: %s", err.Error())
+	}
+	if !strings.Contains(mail.Body, "code=abc123") {
+		t.Errorf("expected body to contain the activation code, got: %q", mail.Body)
+	}
+	if !strings.Contains(mail.Body, "email=pending@example.com") {
+		t.Errorf("expected body to contain the pending address, got: %q", mail.Body)
+	}
+}
+
+func TestMailRendererRendersAdminNoticeTemplate(t *testing.T) {
+	mail, err := testMailRenderer().Render("email/admin_notice.tmpl", emailAdminNoticeTemplateData{
+		Message: "Your email address old@example.com was removed from your account by an administrator.",
+	})
+	if err != nil {
+		t.Fatalf("rendering admin notice template: %s", err.Error())
+	}
+	if !strings.Contains(mail.Body, "old@example.com") {
+		t.Errorf("expected body to contain the notice message, got: %q", mail.Body)
+	}
+}
+
+func TestMailRendererCustomDirOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	customEmailDir := dir + "/email"
+	if err := os.MkdirAll(customEmailDir, 0755); err != nil {
+		t.Fatalf("creating custom dir: %s", err.Error())
+	}
+	custom := `{{define "subject"}}Custom Subject{{end}}{{define "text"}}custom text{{end}}{{define "html"}}<p>custom html</p>{{end}}`
+	if err := os.WriteFile(customEmailDir+"/added.tmpl", []byte(custom), 0644); err != nil {
+		t.Fatalf("writing custom template: %s", err.Error())
+	}
+
+	renderer := &MailRenderer{TemplatesDir: "../templates", CustomDir: dir}
+	mail, err := renderer.Render("email/added.tmpl", emailAddedTemplateData{Email: "alt@example.com"})
+	if err != nil {
+		t.Fatalf("rendering with custom override: %s", err.Error())
+	}
+	if mail.Subject != "Custom Subject" {
+		t.Errorf("expected custom subject to win, got: %q", mail.Subject)
+	}
+	if !strings.Contains(mail.Body, "custom html") {
+		t.Errorf("expected custom html to win, got: %q", mail.Body)
+	}
+}